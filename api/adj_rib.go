@@ -0,0 +1,149 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const ACCEPT_NDJSON = "application/x-ndjson"
+
+// Http request of curl, stream the adj-rib-in of a neighbor.
+//   -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-in/<remote address of target neighbor>
+func (rs *RestServer) AdjRibIn(w http.ResponseWriter, r *http.Request) {
+	rs.adjRib(w, r, REQ_ADJ_RIB_IN)
+}
+
+// Http request of curl, stream the adj-rib-out of a neighbor.
+//   -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-out/<remote address of target neighbor>
+func (rs *RestServer) AdjRibOut(w http.ResponseWriter, r *http.Request) {
+	rs.adjRib(w, r, REQ_ADJ_RIB_OUT)
+}
+
+// Http request of curl, stream the adj-rib-local of a neighbor.
+//   -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-local/<remote address of target neighbor>
+func (rs *RestServer) AdjRibLocal(w http.ResponseWriter, r *http.Request) {
+	rs.adjRib(w, r, REQ_ADJ_RIB_LOCAL)
+}
+
+// Http request of curl, stream only the best path of adj-rib-local of
+// a neighbor.
+//   -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-local/best/<remote address of target neighbor>
+func (rs *RestServer) AdjRibLocalBest(w http.ResponseWriter, r *http.Request) {
+	rs.adjRib(w, r, REQ_ADJ_RIB_LOCAL_BEST)
+}
+
+// adjRib drains a RestResponseRib's RibCh and writes it out either as
+// ndjson (one prefix object per line, streamed as it is produced) when
+// the client sent "Accept: application/x-ndjson", or as a single
+// buffered, optionally paginated JSON document otherwise. Either form
+// is gzip-compressed when the client sent "Accept-Encoding: gzip".
+func (rs *RestServer) adjRib(w http.ResponseWriter, r *http.Request, reqType int) {
+	remoteAddr := mux.Vars(r)[PARAM_REMOTE_PEER_ADDR]
+
+	req := NewGobgpRequest(reqType, remoteAddr)
+	rs.bgpServerCh <- req
+
+	resInf := <-req.ResponseCh
+	if e := resInf.Err(); e != nil {
+		logger.Debug(e.Error())
+		http.Error(w, e.Error(), http.StatusInternalServerError)
+		return
+	}
+	rib := resInf.(*RestResponseRib)
+
+	out, closeGz := maybeGzip(w, r)
+	defer closeGz()
+
+	if strings.Contains(r.Header.Get("Accept"), ACCEPT_NDJSON) {
+		rs.writeNdjson(out, rib)
+		return
+	}
+	rs.writePaginated(out, r, rib)
+}
+
+func (rs *RestServer) writeNdjson(w http.ResponseWriter, rib *RestResponseRib) {
+	w.Header().Set("Content-Type", ACCEPT_NDJSON)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for prefix := range rib.RibCh {
+		enc.Encode(struct {
+			Prefix string `json:"prefix"`
+		}{prefix})
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func (rs *RestServer) writePaginated(w http.ResponseWriter, r *http.Request, rib *RestResponseRib) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	after := r.URL.Query().Get("after")
+	skipping := after != ""
+
+	prefixes := make([]string, 0, limit)
+	for prefix := range rib.RibCh {
+		if skipping {
+			if prefix == after {
+				skipping = false
+			}
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+		if limit > 0 && len(prefixes) >= limit {
+			// drain the rest so the peer goroutine's producer isn't
+			// left blocked sending into a channel nobody reads.
+			go func(ch chan string) {
+				for range ch {
+				}
+			}(rib.RibCh)
+			break
+		}
+	}
+
+	res := struct {
+		RestResponseDefault
+		RemoteAddr string   `json:"remoteAddr"`
+		RemoteAs   uint32   `json:"remoteAs"`
+		RibInfo    []string `json:"ribInfo"`
+	}{rib.RestResponseDefault, rib.RemoteAddr, rib.RemoteAs, prefixes}
+
+	var jns []byte
+	var err error
+	switch JsonFormat {
+	case JSON_FORMATTED:
+		jns, err = json.MarshalIndent(res, "", "  ")
+	case JSON_UN_FORMATTED:
+		jns, err = json.Marshal(res)
+	}
+	if err != nil {
+		logger.Errorf("failed to marshal json of %s: %s", rib.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(jns)
+}
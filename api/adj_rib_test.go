@@ -0,0 +1,150 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func ribWithPrefixes(prefixes ...string) *RestResponseRib {
+	ch := make(chan string, len(prefixes))
+	for _, p := range prefixes {
+		ch <- p
+	}
+	close(ch)
+	return &RestResponseRib{
+		RemoteAddr: "10.0.0.1",
+		RemoteAs:   65000,
+		RibCh:      ch,
+	}
+}
+
+func decodePaginated(t *testing.T, body []byte) []string {
+	t.Helper()
+	var res struct {
+		RibInfo []string `json:"ribInfo"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatalf("failed to unmarshal response: %s", err)
+	}
+	return res.RibInfo
+}
+
+func TestWriteNdjsonOverGzip(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1", nil)
+	r.Header.Set("Accept", ACCEPT_NDJSON)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	out, closeGz := maybeGzip(w, r)
+	rs.writeNdjson(out, ribWithPrefixes("10.0.0.0/24", "10.0.1.0/24"))
+	closeGz()
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding: gzip, got %q", got)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %s", err)
+	}
+	defer gr.Close()
+	plain, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("failed to decompress response body: %s", err)
+	}
+
+	var prefixes []string
+	scanner := bufio.NewScanner(bytes.NewReader(plain))
+	for scanner.Scan() {
+		var line struct {
+			Prefix string `json:"prefix"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("failed to unmarshal ndjson line %q: %s", scanner.Text(), err)
+		}
+		prefixes = append(prefixes, line.Prefix)
+	}
+	want := []string{"10.0.0.0/24", "10.0.1.0/24"}
+	if len(prefixes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, prefixes)
+	}
+	for i := range want {
+		if prefixes[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, prefixes)
+		}
+	}
+}
+
+func TestWritePaginatedEmptyRib(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1", nil)
+
+	rs.writePaginated(w, r, ribWithPrefixes())
+
+	got := decodePaginated(t, w.Body.Bytes())
+	if len(got) != 0 {
+		t.Fatalf("expected no prefixes for an empty RIB, got %v", got)
+	}
+}
+
+func TestWritePaginatedAfterCursorNotFound(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1?after=203.0.113.0/24", nil)
+
+	rs.writePaginated(w, r, ribWithPrefixes("10.0.0.0/24", "10.0.1.0/24"))
+
+	got := decodePaginated(t, w.Body.Bytes())
+	if len(got) != 0 {
+		t.Fatalf("expected no prefixes when the after cursor never matches, got %v", got)
+	}
+}
+
+func TestWritePaginatedLimitZeroReturnsEverything(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1?limit=0", nil)
+
+	prefixes := []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"}
+	rs.writePaginated(w, r, ribWithPrefixes(prefixes...))
+
+	got := decodePaginated(t, w.Body.Bytes())
+	if len(got) != len(prefixes) {
+		t.Fatalf("limit=0 should not truncate results, got %v", got)
+	}
+}
+
+func TestWritePaginatedLimitTruncates(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1?limit=2", nil)
+
+	rs.writePaginated(w, r, ribWithPrefixes("10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"))
+
+	got := decodePaginated(t, w.Body.Bytes())
+	if len(got) != 2 {
+		t.Fatalf("expected limit=2 to truncate to 2 prefixes, got %v", got)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	bgpapi "github.com/ilsanbao/gobgp/api"
+)
+
+// consulDiscoverer watches a Consul KV prefix for peer configuration
+// and publishes this router's presence under a session-backed key so
+// it expires if the router disappears without deregistering.
+type consulDiscoverer struct {
+	cfg         Config
+	client      *consulapi.Client
+	bgpServerCh chan *bgpapi.GobgpRequest
+
+	lastIndex uint64
+	sessionId string
+	stopCh    chan struct{}
+}
+
+func newConsulDiscoverer(cfg Config, bgpServerCh chan *bgpapi.GobgpRequest) (*consulDiscoverer, error) {
+	ccfg := consulapi.DefaultConfig()
+	if len(cfg.Addrs) > 0 {
+		ccfg.Address = cfg.Addrs[0]
+	}
+	client, err := consulapi.NewClient(ccfg)
+	if err != nil {
+		return nil, err
+	}
+	return &consulDiscoverer{
+		cfg:         cfg,
+		client:      client,
+		bgpServerCh: bgpServerCh,
+		stopCh:      make(chan struct{}),
+	}, nil
+}
+
+func (d *consulDiscoverer) Watch() error {
+	kv := d.client.KV()
+	seen := make(map[string]bool)
+	for {
+		select {
+		case <-d.stopCh:
+			return nil
+		default:
+		}
+
+		pairs, meta, err := kv.List(d.cfg.PeerPrefix, &consulapi.QueryOptions{
+			WaitIndex: d.lastIndex,
+			WaitTime:  30 * time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		if meta.LastIndex == d.lastIndex {
+			continue
+		}
+		d.lastIndex = meta.LastIndex
+
+		current := make(map[string]bool, len(pairs))
+		for _, pair := range pairs {
+			current[pair.Key] = true
+			nc := &bgpapi.NeighborConfig{}
+			if err := json.Unmarshal(pair.Value, nc); err != nil {
+				logger.Debugf("discovery: bad neighbor config at %s: %s", pair.Key, err)
+				continue
+			}
+			addOrUpdateNeighbor(d.bgpServerCh, nc)
+		}
+		for key := range seen {
+			if !current[key] {
+				deleteNeighbor(d.bgpServerCh, strings.TrimPrefix(key, d.cfg.PeerPrefix))
+			}
+		}
+		seen = current
+	}
+}
+
+func (d *consulDiscoverer) Register(self Presence) error {
+	ttl := d.cfg.TTL
+	if ttl == 0 {
+		ttl = 30 * time.Second
+	}
+	session := d.client.Session()
+	sessionId, _, err := session.Create(&consulapi.SessionEntry{
+		Name:     d.cfg.SelfKey,
+		TTL:      ttl.String(),
+		Behavior: consulapi.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	d.sessionId = sessionId
+
+	body, err := json.Marshal(self)
+	if err != nil {
+		return err
+	}
+	kv := d.client.KV()
+	if _, _, err := kv.Acquire(&consulapi.KVPair{
+		Key:     d.cfg.SelfKey,
+		Value:   body,
+		Session: sessionId,
+	}, nil); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stopCh:
+				session.Destroy(sessionId, nil)
+				return
+			case <-ticker.C:
+				if _, _, err := session.Renew(sessionId, nil); err != nil {
+					logger.Debugf("discovery: failed to renew consul session: %s", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *consulDiscoverer) Close() error {
+	close(d.stopCh)
+	return nil
+}
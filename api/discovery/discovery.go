@@ -0,0 +1,127 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package discovery watches an external service registry (Consul or
+// etcd) for per-neighbor configuration and issues the matching
+// REQ_ADD_NEIGHBOR / REQ_UPDATE_NEIGHBOR / REQ_DEL_NEIGHBOR requests on
+// bgpServerCh as keys appear, change or disappear. It coexists with the
+// static REST configuration path added for peer mutation; a Discoverer
+// is just another writer on the same channel.
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	bgpapi "github.com/ilsanbao/gobgp/api"
+)
+
+var logger *log.Logger = &log.Logger{
+	Out:       os.Stderr,
+	Formatter: new(log.JSONFormatter),
+	Hooks:     make(map[log.Level][]log.Hook),
+	Level:     log.InfoLevel,
+}
+
+// Discoverer is implemented by each registry backend.
+type Discoverer interface {
+	// Watch blocks, applying peer add/update/delete requests as keys
+	// under Config.PeerPrefix change. It returns when the watch can
+	// no longer be continued, e.g. the registry connection is lost.
+	Watch() error
+
+	// Register publishes this router's presence and current neighbor
+	// states back to the registry under a lease, and keeps renewing
+	// that lease until Close is called.
+	Register(self Presence) error
+
+	Close() error
+}
+
+// Presence is what a gobgp instance advertises about itself under
+// Config.SelfKey.
+type Presence struct {
+	RouterId  string                        `json:"routerId"`
+	LocalAs   uint32                        `json:"localAs"`
+	Neighbors []bgpapi.RestResponseNeighbor `json:"neighbors"`
+}
+
+// Config is the set of discovery parameters sourced from CLI flags.
+type Config struct {
+	// Backend selects the registry implementation: "consul" or "etcd".
+	Backend string
+	// Addrs are the registry endpoints, e.g. "127.0.0.1:8500" for
+	// Consul or "127.0.0.1:2379" for etcd.
+	Addrs []string
+	// PeerPrefix is the keyspace watched for neighbor configuration,
+	// e.g. "gobgp/peers/".
+	PeerPrefix string
+	// SelfKey is where this router's own Presence is published.
+	SelfKey string
+	// TTL is the lease lifetime used by Register.
+	TTL time.Duration
+}
+
+// New builds the Discoverer for cfg.Backend.
+func New(cfg Config, bgpServerCh chan *bgpapi.GobgpRequest) (Discoverer, error) {
+	switch cfg.Backend {
+	case "consul":
+		return newConsulDiscoverer(cfg, bgpServerCh)
+	case "etcd":
+		return newEtcdDiscoverer(cfg, bgpServerCh)
+	default:
+		return nil, fmt.Errorf("discovery: unknown backend %q", cfg.Backend)
+	}
+}
+
+// addOrUpdateNeighbor pushes a REQ_ADD_NEIGHBOR request for cfg and drains the
+// response, falling back to REQ_UPDATE_NEIGHBOR only when the peer
+// already exists -- a registry key can be observed as a fresh Put for a
+// peer this router already learned about statically via REST. Any
+// other failure (bad AS number, unsupported family, ...) is a real
+// config error and is logged instead of being silently retried as an
+// update, which would otherwise double the round trip and mask the
+// original cause.
+func addOrUpdateNeighbor(bgpServerCh chan *bgpapi.GobgpRequest, cfg *bgpapi.NeighborConfig) {
+	req := bgpapi.NewGobgpRequest(bgpapi.REQ_ADD_NEIGHBOR, cfg.RemoteAddr)
+	req.NeighborConfig = cfg
+	bgpServerCh <- req
+	res := <-req.ResponseCh
+	err := res.Err()
+	if err == nil {
+		return
+	}
+	if ce, ok := err.(*bgpapi.ConfigError); !ok || ce.Code != bgpapi.ErrDuplicatePeer {
+		logger.Debugf("discovery: failed to add neighbor %s: %s", cfg.RemoteAddr, err)
+		return
+	}
+
+	req = bgpapi.NewGobgpRequest(bgpapi.REQ_UPDATE_NEIGHBOR, cfg.RemoteAddr)
+	req.NeighborConfig = cfg
+	bgpServerCh <- req
+	if res := <-req.ResponseCh; res.Err() != nil {
+		logger.Debugf("discovery: failed to apply neighbor %s: %s", cfg.RemoteAddr, res.Err())
+	}
+}
+
+func deleteNeighbor(bgpServerCh chan *bgpapi.GobgpRequest, remoteAddr string) {
+	req := bgpapi.NewGobgpRequest(bgpapi.REQ_DEL_NEIGHBOR, remoteAddr)
+	bgpServerCh <- req
+	if res := <-req.ResponseCh; res.Err() != nil {
+		logger.Debugf("discovery: failed to remove neighbor %s: %s", remoteAddr, res.Err())
+	}
+}
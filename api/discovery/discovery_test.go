@@ -0,0 +1,96 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"errors"
+	"testing"
+
+	bgpapi "github.com/ilsanbao/gobgp/api"
+)
+
+func TestAddOrUpdateNeighborRetriesOnlyOnDuplicatePeer(t *testing.T) {
+	bgpServerCh := make(chan *bgpapi.GobgpRequest, 1)
+	cfg := &bgpapi.NeighborConfig{RemoteAddr: "10.0.0.1"}
+
+	go func() {
+		add := <-bgpServerCh
+		if add.RequestType != bgpapi.REQ_ADD_NEIGHBOR {
+			t.Errorf("expected REQ_ADD_NEIGHBOR first, got %d", add.RequestType)
+		}
+		add.ResponseCh <- &bgpapi.RestResponseNeighbor{
+			RestResponseDefault: bgpapi.RestResponseDefault{
+				ResponseErr: &bgpapi.ConfigError{Code: bgpapi.ErrDuplicatePeer, Msg: "peer exists"},
+			},
+		}
+
+		update := <-bgpServerCh
+		if update.RequestType != bgpapi.REQ_UPDATE_NEIGHBOR {
+			t.Errorf("expected REQ_UPDATE_NEIGHBOR fallback, got %d", update.RequestType)
+		}
+		update.ResponseCh <- &bgpapi.RestResponseNeighbor{}
+	}()
+
+	addOrUpdateNeighbor(bgpServerCh, cfg)
+}
+
+func TestAddOrUpdateNeighborDoesNotRetryOnOtherErrors(t *testing.T) {
+	bgpServerCh := make(chan *bgpapi.GobgpRequest, 1)
+	cfg := &bgpapi.NeighborConfig{RemoteAddr: "10.0.0.1"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		add := <-bgpServerCh
+		add.ResponseCh <- &bgpapi.RestResponseNeighbor{
+			RestResponseDefault: bgpapi.RestResponseDefault{
+				ResponseErr: &bgpapi.ConfigError{Code: bgpapi.ErrUnsupportedFamily, Msg: "bad family"},
+			},
+		}
+
+		select {
+		case <-bgpServerCh:
+			t.Error("addOrUpdateNeighbor should not have retried a non-duplicate-peer error")
+		default:
+		}
+	}()
+
+	addOrUpdateNeighbor(bgpServerCh, cfg)
+	<-done
+}
+
+func TestAddOrUpdateNeighborDoesNotRetryOnOpaqueError(t *testing.T) {
+	bgpServerCh := make(chan *bgpapi.GobgpRequest, 1)
+	cfg := &bgpapi.NeighborConfig{RemoteAddr: "10.0.0.1"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		add := <-bgpServerCh
+		add.ResponseCh <- &bgpapi.RestResponseNeighbor{
+			RestResponseDefault: bgpapi.RestResponseDefault{ResponseErr: errors.New("boom")},
+		}
+
+		select {
+		case <-bgpServerCh:
+			t.Error("addOrUpdateNeighbor should not have retried an opaque error")
+		default:
+		}
+	}()
+
+	addOrUpdateNeighbor(bgpServerCh, cfg)
+	<-done
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"golang.org/x/net/context"
+
+	bgpapi "github.com/ilsanbao/gobgp/api"
+)
+
+// etcdDiscoverer watches an etcd v3 keyspace for peer configuration
+// and publishes this router's presence under a TTL lease, renewing it
+// for as long as Close has not been called.
+type etcdDiscoverer struct {
+	cfg         Config
+	client      *clientv3.Client
+	bgpServerCh chan *bgpapi.GobgpRequest
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newEtcdDiscoverer(cfg Config, bgpServerCh chan *bgpapi.GobgpRequest) (*etcdDiscoverer, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Addrs,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &etcdDiscoverer{
+		cfg:         cfg,
+		client:      client,
+		bgpServerCh: bgpServerCh,
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+func (d *etcdDiscoverer) applyPeer(key, value []byte) {
+	nc := &bgpapi.NeighborConfig{}
+	if err := json.Unmarshal(value, nc); err != nil {
+		logger.Debugf("discovery: bad neighbor config at %s: %s", key, err)
+		return
+	}
+	addOrUpdateNeighbor(d.bgpServerCh, nc)
+}
+
+func (d *etcdDiscoverer) applyDelete(key []byte) {
+	remoteAddr := strings.TrimPrefix(string(key), d.cfg.PeerPrefix)
+	deleteNeighbor(d.bgpServerCh, remoteAddr)
+}
+
+func (d *etcdDiscoverer) Watch() error {
+	resp, err := d.client.Get(d.ctx, d.cfg.PeerPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	for _, kv := range resp.Kvs {
+		d.applyPeer(kv.Key, kv.Value)
+	}
+
+	watchCh := d.client.Watch(d.ctx, d.cfg.PeerPrefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for wresp := range watchCh {
+		if err := wresp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range wresp.Events {
+			switch ev.Type {
+			case clientv3.EventTypePut:
+				d.applyPeer(ev.Kv.Key, ev.Kv.Value)
+			case clientv3.EventTypeDelete:
+				d.applyDelete(ev.Kv.Key)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *etcdDiscoverer) Register(self Presence) error {
+	ttl := d.cfg.TTL
+	if ttl == 0 {
+		ttl = 30 * time.Second
+	}
+	lease, err := d.client.Grant(d.ctx, int64(ttl.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(self)
+	if err != nil {
+		return err
+	}
+	if _, err := d.client.Put(d.ctx, d.cfg.SelfKey, string(body), clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+
+	keepAliveCh, err := d.client.KeepAlive(d.ctx, lease.ID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range keepAliveCh {
+			// etcd's KeepAlive consumes responses internally; we just
+			// need to drain the channel until the context is cancelled.
+		}
+	}()
+	return nil
+}
+
+func (d *etcdDiscoverer) Close() error {
+	d.cancel()
+	return d.client.Close()
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const GRPC_PORT = 8081
+
+// GrpcServer answers the same GobgpRequests as RestServer over a
+// protobuf/gRPC transport instead of JSON/HTTP, so the two servers can
+// run side by side against one bgpServerCh and share a single request
+// handler written once against the peer goroutine.
+type GrpcServer struct {
+	port        int
+	bgpServerCh chan *GobgpRequest
+}
+
+func NewGrpcServer(port int, bgpServerCh chan *GobgpRequest) *GrpcServer {
+	gs := &GrpcServer{
+		port:        port,
+		bgpServerCh: bgpServerCh,
+	}
+	return gs
+}
+
+func (gs *GrpcServer) Serve() {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", gs.port))
+	if err != nil {
+		logger.Errorf("failed to listen on grpc port %d: %s", gs.port, err)
+		return
+	}
+	s := grpc.NewServer()
+	RegisterGrpcServer(s, gs)
+	s.Serve(lis)
+}
+
+// request sends req over bgpServerCh and waits for the peer goroutine's
+// response, the same round trip RestServer.Neighbor performs.
+func (gs *GrpcServer) request(reqType int, remoteAddr string) (RestResponse, error) {
+	req := NewGobgpRequest(reqType, remoteAddr)
+	gs.bgpServerCh <- req
+	res := <-req.ResponseCh
+	if e := res.Err(); e != nil {
+		return nil, e
+	}
+	return res, nil
+}
+
+func (gs *GrpcServer) GetNeighbor(ctx context.Context, arg *Arg) (*Neighbor, error) {
+	res, err := gs.request(REQ_NEIGHBOR, arg.RemoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	n := res.(*RestResponseNeighbor)
+	return &Neighbor{
+		RemoteAddr:    n.RemoteAddr,
+		RemoteAs:      n.RemoteAs,
+		NeighborState: n.NeighborState,
+		UpdateCount:   int32(n.UpdateCount),
+	}, nil
+}
+
+func (gs *GrpcServer) ListNeighbors(arg *Arg, stream Grpc_ListNeighborsServer) error {
+	req := NewGobgpRequest(REQ_NEIGHBORS, arg.RemoteAddr)
+	gs.bgpServerCh <- req
+	// REQ_NEIGHBORS returns one RestResponseNeighbor per configured
+	// peer; the peer goroutine closes ResponseCh once it has sent them
+	// all instead of the single reply used by REQ_NEIGHBOR.
+	for res := range req.ResponseCh {
+		if e := res.Err(); e != nil {
+			return e
+		}
+		n := res.(*RestResponseNeighbor)
+		if err := stream.Send(&Neighbor{
+			RemoteAddr:    n.RemoteAddr,
+			RemoteAs:      n.RemoteAs,
+			NeighborState: n.NeighborState,
+			UpdateCount:   int32(n.UpdateCount),
+		}); err != nil {
+			// the client went away mid-stream; drain the rest of
+			// ResponseCh so the peer goroutine's producer isn't left
+			// blocked sending into a channel nobody reads.
+			drainResponseCh(req.ResponseCh)
+			return err
+		}
+	}
+	return nil
+}
+
+// drainResponseCh reads ch to completion in the background. Used when
+// a streaming RPC bails out early and would otherwise leave the peer
+// goroutine producing into it blocked forever.
+func drainResponseCh(ch chan RestResponse) {
+	go func() {
+		for range ch {
+		}
+	}()
+}
+
+// drainRibCh is drainResponseCh's counterpart for RestResponseRib.RibCh.
+func drainRibCh(ch chan string) {
+	go func() {
+		for range ch {
+		}
+	}()
+}
+
+func (gs *GrpcServer) adjRib(reqType int, arg *Arg, send func(*Path) error) error {
+	res, err := gs.request(reqType, arg.RemoteAddr)
+	if err != nil {
+		return err
+	}
+	rib := res.(*RestResponseRib)
+	for prefix := range rib.RibCh {
+		if err := send(&Path{Prefix: prefix}); err != nil {
+			// the client went away mid-stream; drain the rest of
+			// RibCh so the peer goroutine's producer isn't left
+			// blocked sending into a channel nobody reads.
+			drainRibCh(rib.RibCh)
+			return err
+		}
+	}
+	return nil
+}
+
+func (gs *GrpcServer) GetAdjRibIn(arg *Arg, stream Grpc_GetAdjRibInServer) error {
+	return gs.adjRib(REQ_ADJ_RIB_IN, arg, stream.Send)
+}
+
+func (gs *GrpcServer) GetAdjRibOut(arg *Arg, stream Grpc_GetAdjRibOutServer) error {
+	return gs.adjRib(REQ_ADJ_RIB_OUT, arg, stream.Send)
+}
+
+func (gs *GrpcServer) GetAdjRibLocal(arg *Arg, stream Grpc_GetAdjRibLocalServer) error {
+	return gs.adjRib(REQ_ADJ_RIB_LOCAL, arg, stream.Send)
+}
+
+// Subscribe streams neighbor state transitions, adj-rib-in updates and
+// adj-rib-local best path changes, the gRPC counterpart of the
+// websocket RestServer.Subscribe -- both transports dispatch through
+// the shared subscribeEvents helper instead of duplicating the
+// per-topic fan-out.
+func (gs *GrpcServer) Subscribe(arg *SubscribeArg, stream Grpc_SubscribeServer) error {
+	filter := SubscribeFilter{RemoteAddr: arg.RemoteAddr, RibType: arg.RibType}
+	events, stop := subscribeEvents(gs.bgpServerCh, filter)
+	defer stop()
+
+	for {
+		select {
+		case ev := <-events:
+			data, err := json.Marshal(ev.Data)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&Event{
+				Type:       ev.Type,
+				RemoteAddr: ev.RemoteAddr,
+				DataJson:   string(data),
+			}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
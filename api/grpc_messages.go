@@ -0,0 +1,412 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Hand-written to mirror gobgp.proto until the build picks up a
+// protoc/protoc-gen-go step; nothing here is generated, so it's fair
+// game to edit, and it must be kept in sync with gobgp.proto by hand.
+
+package api
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type Arg struct {
+	RemoteAddr string `protobuf:"bytes,1,opt,name=remote_addr,json=remoteAddr" json:"remote_addr,omitempty"`
+}
+
+type Neighbor struct {
+	RemoteAddr    string `protobuf:"bytes,1,opt,name=remote_addr,json=remoteAddr" json:"remote_addr,omitempty"`
+	RemoteAs      uint32 `protobuf:"varint,2,opt,name=remote_as,json=remoteAs" json:"remote_as,omitempty"`
+	NeighborState uint32 `protobuf:"varint,3,opt,name=neighbor_state,json=neighborState" json:"neighbor_state,omitempty"`
+	UpdateCount   int32  `protobuf:"varint,4,opt,name=update_count,json=updateCount" json:"update_count,omitempty"`
+}
+
+type Path struct {
+	Prefix string `protobuf:"bytes,1,opt,name=prefix" json:"prefix,omitempty"`
+}
+
+type SubscribeArg struct {
+	RemoteAddr string `protobuf:"bytes,1,opt,name=remote_addr,json=remoteAddr" json:"remote_addr,omitempty"`
+	RibType    string `protobuf:"bytes,2,opt,name=rib_type,json=ribType" json:"rib_type,omitempty"`
+}
+
+type Event struct {
+	Type       string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	RemoteAddr string `protobuf:"bytes,2,opt,name=remote_addr,json=remoteAddr" json:"remote_addr,omitempty"`
+	DataJson   string `protobuf:"bytes,3,opt,name=data_json,json=dataJson" json:"data_json,omitempty"`
+}
+
+// Client API for Grpc service
+
+type GrpcClient interface {
+	GetNeighbor(ctx context.Context, in *Arg, opts ...grpc.CallOption) (*Neighbor, error)
+	ListNeighbors(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_ListNeighborsClient, error)
+	GetAdjRibIn(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibInClient, error)
+	GetAdjRibOut(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibOutClient, error)
+	GetAdjRibLocal(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibLocalClient, error)
+	Subscribe(ctx context.Context, in *SubscribeArg, opts ...grpc.CallOption) (Grpc_SubscribeClient, error)
+}
+
+type grpcClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGrpcClient(cc *grpc.ClientConn) GrpcClient {
+	return &grpcClient{cc}
+}
+
+func (c *grpcClient) GetNeighbor(ctx context.Context, in *Arg, opts ...grpc.CallOption) (*Neighbor, error) {
+	out := new(Neighbor)
+	if err := grpc.Invoke(ctx, "/api.Grpc/GetNeighbor", in, out, c.cc, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcClient) ListNeighbors(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_ListNeighborsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Grpc_serviceDesc.Streams[0], c.cc, "/api.Grpc/ListNeighbors", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpcListNeighborsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Grpc_ListNeighborsClient interface {
+	Recv() (*Neighbor, error)
+	grpc.ClientStream
+}
+
+type grpcListNeighborsClient struct {
+	grpc.ClientStream
+}
+
+func (x *grpcListNeighborsClient) Recv() (*Neighbor, error) {
+	m := new(Neighbor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *grpcClient) GetAdjRibIn(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibInClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Grpc_serviceDesc.Streams[1], c.cc, "/api.Grpc/GetAdjRibIn", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpcGetAdjRibInClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Grpc_GetAdjRibInClient interface {
+	Recv() (*Path, error)
+	grpc.ClientStream
+}
+
+type grpcGetAdjRibInClient struct {
+	grpc.ClientStream
+}
+
+func (x *grpcGetAdjRibInClient) Recv() (*Path, error) {
+	m := new(Path)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *grpcClient) GetAdjRibOut(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibOutClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Grpc_serviceDesc.Streams[2], c.cc, "/api.Grpc/GetAdjRibOut", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpcGetAdjRibOutClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Grpc_GetAdjRibOutClient interface {
+	Recv() (*Path, error)
+	grpc.ClientStream
+}
+
+type grpcGetAdjRibOutClient struct {
+	grpc.ClientStream
+}
+
+func (x *grpcGetAdjRibOutClient) Recv() (*Path, error) {
+	m := new(Path)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *grpcClient) GetAdjRibLocal(ctx context.Context, in *Arg, opts ...grpc.CallOption) (Grpc_GetAdjRibLocalClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Grpc_serviceDesc.Streams[3], c.cc, "/api.Grpc/GetAdjRibLocal", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpcGetAdjRibLocalClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Grpc_GetAdjRibLocalClient interface {
+	Recv() (*Path, error)
+	grpc.ClientStream
+}
+
+type grpcGetAdjRibLocalClient struct {
+	grpc.ClientStream
+}
+
+func (x *grpcGetAdjRibLocalClient) Recv() (*Path, error) {
+	m := new(Path)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *grpcClient) Subscribe(ctx context.Context, in *SubscribeArg, opts ...grpc.CallOption) (Grpc_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Grpc_serviceDesc.Streams[4], c.cc, "/api.Grpc/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpcSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Grpc_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type grpcSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *grpcSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Grpc service
+
+type GrpcServerAPI interface {
+	GetNeighbor(context.Context, *Arg) (*Neighbor, error)
+	ListNeighbors(*Arg, Grpc_ListNeighborsServer) error
+	GetAdjRibIn(*Arg, Grpc_GetAdjRibInServer) error
+	GetAdjRibOut(*Arg, Grpc_GetAdjRibOutServer) error
+	GetAdjRibLocal(*Arg, Grpc_GetAdjRibLocalServer) error
+	Subscribe(*SubscribeArg, Grpc_SubscribeServer) error
+}
+
+func RegisterGrpcServer(s *grpc.Server, srv GrpcServerAPI) {
+	s.RegisterService(&_Grpc_serviceDesc, srv)
+}
+
+func _Grpc_GetNeighbor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Arg)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcServerAPI).GetNeighbor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/api.Grpc/GetNeighbor"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcServerAPI).GetNeighbor(ctx, req.(*Arg))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Grpc_ListNeighbors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Arg)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcServerAPI).ListNeighbors(m, &grpcListNeighborsServer{stream})
+}
+
+type Grpc_ListNeighborsServer interface {
+	Send(*Neighbor) error
+	grpc.ServerStream
+}
+
+type grpcListNeighborsServer struct {
+	grpc.ServerStream
+}
+
+func (x *grpcListNeighborsServer) Send(m *Neighbor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Grpc_GetAdjRibIn_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Arg)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcServerAPI).GetAdjRibIn(m, &grpcGetAdjRibInServer{stream})
+}
+
+type Grpc_GetAdjRibInServer interface {
+	Send(*Path) error
+	grpc.ServerStream
+}
+
+type grpcGetAdjRibInServer struct {
+	grpc.ServerStream
+}
+
+func (x *grpcGetAdjRibInServer) Send(m *Path) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Grpc_GetAdjRibOut_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Arg)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcServerAPI).GetAdjRibOut(m, &grpcGetAdjRibOutServer{stream})
+}
+
+type Grpc_GetAdjRibOutServer interface {
+	Send(*Path) error
+	grpc.ServerStream
+}
+
+type grpcGetAdjRibOutServer struct {
+	grpc.ServerStream
+}
+
+func (x *grpcGetAdjRibOutServer) Send(m *Path) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Grpc_GetAdjRibLocal_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Arg)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcServerAPI).GetAdjRibLocal(m, &grpcGetAdjRibLocalServer{stream})
+}
+
+type Grpc_GetAdjRibLocalServer interface {
+	Send(*Path) error
+	grpc.ServerStream
+}
+
+type grpcGetAdjRibLocalServer struct {
+	grpc.ServerStream
+}
+
+func (x *grpcGetAdjRibLocalServer) Send(m *Path) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Grpc_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeArg)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcServerAPI).Subscribe(m, &grpcSubscribeServer{stream})
+}
+
+type Grpc_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type grpcSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *grpcSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Grpc_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Grpc",
+	HandlerType: (*GrpcServerAPI)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNeighbor",
+			Handler:    _Grpc_GetNeighbor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListNeighbors",
+			Handler:       _Grpc_ListNeighbors_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAdjRibIn",
+			Handler:       _Grpc_GetAdjRibIn_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAdjRibOut",
+			Handler:       _Grpc_GetAdjRibOut_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAdjRibLocal",
+			Handler:       _Grpc_GetAdjRibLocal_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Grpc_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gobgp.proto",
+}
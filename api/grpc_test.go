@@ -0,0 +1,64 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestGrpcServerGetNeighbor(t *testing.T) {
+	bgpServerCh := make(chan *GobgpRequest, 1)
+	gs := NewGrpcServer(GRPC_PORT, bgpServerCh)
+
+	go func() {
+		req := <-bgpServerCh
+		req.ResponseCh <- &RestResponseNeighbor{
+			RemoteAddr:    req.RemoteAddr,
+			RemoteAs:      65000,
+			NeighborState: 6,
+			UpdateCount:   3,
+		}
+	}()
+
+	n, err := gs.GetNeighbor(context.Background(), &Arg{RemoteAddr: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n.RemoteAddr != "10.0.0.1" || n.RemoteAs != 65000 || n.NeighborState != 6 || n.UpdateCount != 3 {
+		t.Fatalf("unexpected neighbor: %+v", n)
+	}
+}
+
+func TestGrpcServerGetNeighborPropagatesError(t *testing.T) {
+	bgpServerCh := make(chan *GobgpRequest, 1)
+	gs := NewGrpcServer(GRPC_PORT, bgpServerCh)
+
+	wantErr := errTest("no such neighbor")
+	go func() {
+		req := <-bgpServerCh
+		req.ResponseCh <- &RestResponseNeighbor{RestResponseDefault: RestResponseDefault{ResponseErr: wantErr}}
+	}()
+
+	if _, err := gs.GetNeighbor(context.Background(), &Arg{RemoteAddr: "10.0.0.1"}); err != wantErr {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+}
+
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
@@ -0,0 +1,62 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// gzipResponseWriter wraps an http.ResponseWriter so Write goes through
+// a gzip.Writer while headers/status still go to the real response.
+type gzipResponseWriter struct {
+	gz *gzip.Writer
+	http.ResponseWriter
+}
+
+func (w gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+// Flush lets streamed handlers like writeNdjson push each line to the
+// client as it is produced instead of buffering it behind gz until
+// closeGz runs. Embedding http.ResponseWriter alone would never
+// promote Flush -- its method set is exactly the three
+// http.ResponseWriter methods, regardless of what the concrete writer
+// underneath supports -- so it has to be implemented here explicitly.
+func (w gzipResponseWriter) Flush() {
+	w.gz.Flush()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+}
+
+// maybeGzip wraps w in a gzip.Writer and sets Content-Encoding when the
+// client sent Accept-Encoding: gzip. The returned close func must be
+// called (deferred) once the handler is done writing.
+func maybeGzip(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if !acceptsGzip(r) {
+		return w, func() {}
+	}
+	gz := gzip.NewWriter(w)
+	w.Header().Set("Content-Encoding", "gzip")
+	return gzipResponseWriter{gz: gz, ResponseWriter: w}, func() { gz.Close() }
+}
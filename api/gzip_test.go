@@ -0,0 +1,105 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaybeGzipRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	out, closeGz := maybeGzip(w, r)
+	out.Write([]byte("hello rib"))
+	closeGz()
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding: gzip, got %q", got)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %s", err)
+	}
+	defer gr.Close()
+	plain, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("failed to decompress response body: %s", err)
+	}
+	if string(plain) != "hello rib" {
+		t.Fatalf("expected %q after decompression, got %q", "hello rib", plain)
+	}
+}
+
+func TestMaybeGzipFlushPropagatesToUnderlyingWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	out, closeGz := maybeGzip(w, r)
+	defer closeGz()
+
+	flusher, ok := out.(http.Flusher)
+	if !ok {
+		t.Fatalf("expected gzipResponseWriter to implement http.Flusher")
+	}
+
+	out.Write([]byte("hello rib\n"))
+	flusher.Flush()
+
+	if !w.Flushed {
+		t.Fatalf("expected Flush to propagate to the underlying ResponseWriter")
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("response body is not valid gzip after Flush: %s", err)
+	}
+	defer gr.Close()
+	// the stream is not closed yet, so it has no gzip trailer: read
+	// exactly the flushed bytes rather than to EOF.
+	plain := make([]byte, len("hello rib\n"))
+	if _, err := io.ReadFull(gr, plain); err != nil {
+		t.Fatalf("failed to decompress response body after Flush: %s", err)
+	}
+	if string(plain) != "hello rib\n" {
+		t.Fatalf("expected %q after Flush, got %q", "hello rib\n", plain)
+	}
+}
+
+func TestMaybeGzipPassthroughWithoutAcceptEncoding(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/adj-rib-in/10.0.0.1", nil)
+
+	out, closeGz := maybeGzip(w, r)
+	out.Write([]byte("hello rib"))
+	closeGz()
+
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected no Content-Encoding header, got %q", got)
+	}
+	if got := w.Body.String(); got != "hello rib" {
+		t.Fatalf("expected passthrough body %q, got %q", "hello rib", got)
+	}
+}
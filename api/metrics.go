@@ -0,0 +1,139 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	neighborState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gobgp",
+		Name:      "neighbor_state",
+		Help:      "Current FSM state of a BGP neighbor (Idle=1..Established).",
+	}, []string{"peer", "asn"})
+
+	updateMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gobgp",
+		Name:      "update_messages_total",
+		Help:      "UPDATE messages exchanged with a peer, by direction.",
+	}, []string{"peer", "direction"})
+
+	ribPrefixes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gobgp",
+		Name:      "rib_prefixes",
+		Help:      "Prefix count per peer, RIB and address family.",
+	}, []string{"peer", "rib", "afi"})
+
+	sessionUptimeSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gobgp",
+		Name:      "session_uptime_seconds",
+		Help:      "Seconds since the neighbor last reached Established.",
+	}, []string{"peer"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "gobgp",
+		Name:      "request_duration_seconds",
+		Help:      "REST request latency; gRPC calls are not recorded.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"transport", "method", "path", "status"})
+)
+
+func init() {
+	prometheus.MustRegister(neighborState, updateMessagesTotal, ribPrefixes, sessionUptimeSeconds, requestDuration)
+}
+
+// SetNeighborState lets the peer goroutine publish a neighbor's FSM
+// state every time it transitions.
+func SetNeighborState(peer string, asn uint32, state uint32) {
+	neighborState.WithLabelValues(peer, strconv.Itoa(int(asn))).Set(float64(state))
+}
+
+// ObserveUpdateMessage counts one UPDATE sent ("out") or received
+// ("in") for peer.
+func ObserveUpdateMessage(peer, direction string) {
+	updateMessagesTotal.WithLabelValues(peer, direction).Inc()
+}
+
+// SetRibPrefixCount publishes the current prefix count for one of
+// "adj-rib-in", "adj-rib-out" or "adj-rib-local".
+func SetRibPrefixCount(peer, rib, afi string, count int) {
+	ribPrefixes.WithLabelValues(peer, rib, afi).Set(float64(count))
+}
+
+func SetSessionUptime(peer string, uptime time.Duration) {
+	sessionUptimeSeconds.WithLabelValues(peer).Set(uptime.Seconds())
+}
+
+// statusWriter records the status code a handler wrote so instrument
+// can label the request_duration_seconds observation with it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes through to the wrapped writer so streamed handlers
+// (writeNdjson, gzipResponseWriter.Flush) still reach the client when
+// running under instrument.
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// instrument wraps h so every call is timed and recorded against
+// request_duration_seconds under the given mux pattern.
+func instrument(path string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h(sw, r)
+		requestDuration.WithLabelValues("rest", r.Method, path, strconv.Itoa(sw.status)).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Http request of curl, Prometheus text exposition format, or a JSON
+// dump of the same samples for scrape-less debugging.
+//   -- curt -i -X GET http://<ownIP>:3000/metrics
+//   -- curt -i -X GET http://<ownIP>:3000/metrics?format=json
+func (rs *RestServer) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "json" {
+		rs.metricsJSON(w)
+		return
+	}
+	promhttp.Handler().ServeHTTP(w, r)
+}
+
+func (rs *RestServer) metricsJSON(w http.ResponseWriter) {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mfs)
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriterDefaultsToOK(t *testing.T) {
+	sw := &statusWriter{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	if sw.status != http.StatusOK {
+		t.Fatalf("expected default status %d, got %d", http.StatusOK, sw.status)
+	}
+}
+
+func TestStatusWriterCapturesWriteHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sw := &statusWriter{ResponseWriter: rec, status: http.StatusOK}
+
+	sw.WriteHeader(http.StatusNotFound)
+
+	if sw.status != http.StatusNotFound {
+		t.Fatalf("expected statusWriter to capture %d, got %d", http.StatusNotFound, sw.status)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected underlying ResponseWriter to see %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestInstrumentPropagatesStatusAndBody(t *testing.T) {
+	handler := instrument("/v1/bgp/neighbor", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "teapot", http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v1/bgp/neighbor/10.0.0.1", nil)
+
+	handler(rec, r)
+
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("expected instrument to propagate status %d, got %d", http.StatusTeapot, rec.Code)
+	}
+	if got := rec.Body.String(); got != "teapot\n" {
+		t.Fatalf("expected instrument to propagate body %q, got %q", "teapot\n", got)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Body of a POST /v1/bgp/neighbor or PUT /v1/bgp/neighbor/{addr}
+// request. Families and Policies are names, e.g. "ipv4-unicast",
+// resolved by the BGP server goroutine that applies the config delta.
+type NeighborConfig struct {
+	RemoteAddr string   `json:"remoteAddr"`
+	RemoteAs   uint32   `json:"remoteAs"`
+	LocalAs    uint32   `json:"localAs"`
+	HoldTime   uint32   `json:"holdTime"`
+	Families   []string `json:"families"`
+	Policies   []string `json:"policies"`
+}
+
+// Body of a POST /v1/bgp/adj-rib-local/{addr} request. Withdraw marks
+// the prefix for withdrawal instead of addition.
+type RouteConfig struct {
+	Prefix   string `json:"prefix"`
+	NextHop  string `json:"nextHop"`
+	Withdraw bool   `json:"withdraw"`
+}
+
+// ConfigError codes classify why a neighbor/route config request
+// failed, so callers -- REST clients, and the discovery package's
+// addOrUpdateNeighbor fallback -- can branch on the failure instead of
+// treating every error as an opaque internal fault.
+const (
+	ErrDuplicatePeer = iota + 1
+	ErrUnsupportedFamily
+	ErrInternal
+)
+
+// ConfigError is what the peer goroutine returns on RestResponseDefault
+// for a failed REQ_ADD_NEIGHBOR / REQ_UPDATE_NEIGHBOR / REQ_DEL_NEIGHBOR
+// / REQ_INJECT_ROUTE request.
+type ConfigError struct {
+	Code int
+	Msg  string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Msg
+}
+
+// configErrorStatus maps a ConfigError's Code to the HTTP status a REST
+// client should see; any other error (including a plain error without a
+// ConfigError behind it) is treated as an internal fault.
+func configErrorStatus(err error) int {
+	ce, ok := err.(*ConfigError)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	switch ce.Code {
+	case ErrDuplicatePeer:
+		return http.StatusConflict
+	case ErrUnsupportedFamily:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// sends req on bgpServerCh, waits for the peer goroutine's reply and
+// writes it out as the usual RestResponseDefault JSON envelope.
+func (rs *RestServer) writeConfigResponse(w http.ResponseWriter, req *GobgpRequest) {
+	rs.bgpServerCh <- req
+	res := <-req.ResponseCh
+	status := http.StatusOK
+	if e := res.Err(); e != nil {
+		logger.Debug(e.Error())
+		status = configErrorStatus(e)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
+
+// Http request of curl, add a new peer.
+//   -- curt -i -X POST -d @neighbor.json http://<ownIP>:3000/v1/bgp/neighbor
+func (rs *RestServer) AddNeighbor(w http.ResponseWriter, r *http.Request) {
+	cfg := &NeighborConfig{}
+	if err := json.NewDecoder(r.Body).Decode(cfg); err != nil {
+		logger.Debugf("failed to decode neighbor config: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := NewGobgpRequest(REQ_ADD_NEIGHBOR, cfg.RemoteAddr)
+	req.NeighborConfig = cfg
+	rs.writeConfigResponse(w, req)
+}
+
+// Http request of curl, update an existing peer's configuration.
+//   -- curt -i -X PUT -d @neighbor.json http://<ownIP>:3000/v1/bgp/neighbor/<remote address of target neighbor>
+func (rs *RestServer) UpdateNeighbor(w http.ResponseWriter, r *http.Request) {
+	remoteAddr := mux.Vars(r)[PARAM_REMOTE_PEER_ADDR]
+
+	cfg := &NeighborConfig{}
+	if err := json.NewDecoder(r.Body).Decode(cfg); err != nil {
+		logger.Debugf("failed to decode neighbor config: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg.RemoteAddr = remoteAddr
+
+	req := NewGobgpRequest(REQ_UPDATE_NEIGHBOR, remoteAddr)
+	req.NeighborConfig = cfg
+	rs.writeConfigResponse(w, req)
+}
+
+// Http request of curl, tear down a peer.
+//   -- curt -i -X DELETE http://<ownIP>:3000/v1/bgp/neighbor/<remote address of target neighbor>
+func (rs *RestServer) DeleteNeighbor(w http.ResponseWriter, r *http.Request) {
+	remoteAddr := mux.Vars(r)[PARAM_REMOTE_PEER_ADDR]
+
+	req := NewGobgpRequest(REQ_DEL_NEIGHBOR, remoteAddr)
+	rs.writeConfigResponse(w, req)
+}
+
+// Http request of curl, inject (or withdraw) a static route into
+// adj-rib-local for a neighbor.
+//   -- curt -i -X POST -d @route.json http://<ownIP>:3000/v1/bgp/adj-rib-local/<remote address of target neighbor>
+func (rs *RestServer) InjectRoute(w http.ResponseWriter, r *http.Request) {
+	remoteAddr := mux.Vars(r)[PARAM_REMOTE_PEER_ADDR]
+
+	route := &RouteConfig{}
+	if err := json.NewDecoder(r.Body).Decode(route); err != nil {
+		logger.Debugf("failed to decode route config: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := NewGobgpRequest(REQ_INJECT_ROUTE, remoteAddr)
+	req.RouteConfig = route
+	rs.writeConfigResponse(w, req)
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddNeighborMalformedBodyReturnsBadRequest(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1/bgp/neighbor", strings.NewReader("{not json"))
+
+	rs.AddNeighbor(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for malformed body, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestUpdateNeighborMalformedBodyReturnsBadRequest(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PUT", "/v1/bgp/neighbor/10.0.0.1", strings.NewReader("{not json"))
+
+	rs.UpdateNeighbor(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for malformed body, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestInjectRouteMalformedBodyReturnsBadRequest(t *testing.T) {
+	rs := &RestServer{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1/bgp/adj-rib-local/10.0.0.1", strings.NewReader("{not json"))
+
+	rs.InjectRoute(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d for malformed body, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestConfigErrorStatus(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{&ConfigError{Code: ErrDuplicatePeer, Msg: "peer exists"}, http.StatusConflict},
+		{&ConfigError{Code: ErrUnsupportedFamily, Msg: "bad family"}, http.StatusBadRequest},
+		{&ConfigError{Code: ErrInternal, Msg: "boom"}, http.StatusInternalServerError},
+		{errors.New("opaque"), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		if got := configErrorStatus(c.err); got != c.want {
+			t.Fatalf("configErrorStatus(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+}
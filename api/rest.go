@@ -40,6 +40,13 @@ const (
 	REQ_ADJ_RIB_OUT
 	REQ_ADJ_RIB_LOCAL
 	REQ_ADJ_RIB_LOCAL_BEST
+	REQ_SUBSCRIBE_NEIGHBOR_STATE
+	REQ_SUBSCRIBE_ADJ_RIB_IN
+	REQ_SUBSCRIBE_ADJ_RIB_LOCAL_BEST
+	REQ_ADD_NEIGHBOR
+	REQ_UPDATE_NEIGHBOR
+	REQ_DEL_NEIGHBOR
+	REQ_INJECT_ROUTE
 )
 const (
 	BASE_VERSION       = "/v1"
@@ -49,6 +56,8 @@ const (
 	ADJ_RIB_OUT        = "/bgp/adj-rib-out"
 	ADJ_RIB_LOCAL      = "/bgp/adj-rib-local"
 	ADJ_RIB_LOCAL_BEST = "/bgp/adj-rib-local/best"
+	SUBSCRIBE          = "/bgp/subscribe"
+	METRICS            = "/metrics"
 
 	PARAM_REMOTE_PEER_ADDR = "remotePeerAddr"
 )
@@ -66,15 +75,28 @@ var JsonFormat int = JSON_FORMATTED
 // trigger struct for exchanging information in the rest and peer.
 // rest and peer operated at different thread.
 
-type RestRequest struct {
+type GobgpRequest struct {
 	RequestType int
 	RemoteAddr  string
 	ResponseCh  chan RestResponse
 	Err         error
+
+	// Set for REQ_SUBSCRIBE_* request types only; carries the topic
+	// filter and the channel the owning peer goroutine pushes
+	// SubscribeEvents into. nil for plain request/response calls.
+	Subscribe *SubscribeRequest
+
+	// Set for REQ_ADD_NEIGHBOR / REQ_UPDATE_NEIGHBOR; carries the
+	// peer configuration decoded from the request body.
+	NeighborConfig *NeighborConfig
+
+	// Set for REQ_INJECT_ROUTE; carries the route to add or withdraw
+	// from adj-rib-local.
+	RouteConfig *RouteConfig
 }
 
-func NewRestRequest(reqType int, remoteAddr string) *RestRequest {
-	r := &RestRequest{
+func NewGobgpRequest(reqType int, remoteAddr string) *GobgpRequest {
+	r := &GobgpRequest{
 		RequestType: reqType,
 		RemoteAddr:  remoteAddr,
 		ResponseCh:  make(chan RestResponse),
@@ -103,20 +125,24 @@ type RestResponseNeighbor struct {
 	UpdateCount   int
 }
 
-// Response struct for Rib
+// Response struct for Rib. Prefixes are pushed onto RibCh by the peer
+// goroutine's producer as it walks the RIB, instead of being
+// materialized into a slice up front -- full-table peers can carry
+// 900k+ prefixes, which is too much to hold in memory at once.
+// RibCh is closed once the whole RIB has been sent.
 type RestResponseRib struct {
 	RestResponseDefault
 	RemoteAddr string
 	RemoteAs   uint32
-	RibInfo    []string
+	RibCh      chan string
 }
 
 type RestServer struct {
 	port        int
-	bgpServerCh chan *RestRequest
+	bgpServerCh chan *GobgpRequest
 }
 
-func NewRestServer(port int, bgpServerCh chan *RestRequest) *RestServer {
+func NewRestServer(port int, bgpServerCh chan *GobgpRequest) *RestServer {
 	rs := &RestServer{
 		port:        port,
 		bgpServerCh: bgpServerCh}
@@ -137,22 +163,42 @@ func NewRestServer(port int, bgpServerCh chan *RestRequest) *RestServer {
 //     -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-local/<remote address of target neighbor>
 //   get only best path of adj-rib-local  of each neighbor.
 //     -- curt -i -X GET http://<ownIP>:3000/v1/bgp/adj-rib-local/best/<remote address of target neighbor>
+//   add a neighbor.
+//     -- curt -i -X POST -d @neighbor.json http://<ownIP>:3000/v1/bgp/neighbor
+//   update a neighbor's configuration.
+//     -- curt -i -X PUT -d @neighbor.json http://<ownIP>:3000/v1/bgp/neighbor/<remote address of target neighbor>
+//   tear down a neighbor.
+//     -- curt -i -X DELETE http://<ownIP>:3000/v1/bgp/neighbor/<remote address of target neighbor>
+//   inject a static route (or withdrawal) into adj-rib-local.
+//     -- curt -i -X POST -d @route.json http://<ownIP>:3000/v1/bgp/adj-rib-local/<remote address of target neighbor>
+//   scrape Prometheus metrics (or fetch them as JSON for debugging).
+//     -- curt -i -X GET http://<ownIP>:3000/metrics
 func (rs *RestServer) Serve() {
 	neighbor := BASE_VERSION + NEIGHBOR
 	// neighbors := BASE_VERSION + NEIGHBORS
-	// adjRibIn := BASE_VERSION + ADJ_RIB_IN
-	// adjRibOut := BASE_VERSION + ADJ_RIB_OUT
-	// adjRibLocal := BASE_VERSION + ADJ_RIB_LOCAL
-	// adjRibLocalBest := BASE_VERSION + ADJ_RIB_LOCAL_BEST
+	adjRibIn := BASE_VERSION + ADJ_RIB_IN
+	adjRibOut := BASE_VERSION + ADJ_RIB_OUT
+	adjRibLocal := BASE_VERSION + ADJ_RIB_LOCAL
+	adjRibLocalBest := BASE_VERSION + ADJ_RIB_LOCAL_BEST
+
+	subscribe := BASE_VERSION + SUBSCRIBE
 
 	r := mux.NewRouter()
-	// set URLs
-	r.HandleFunc(neighbor+"/{"+PARAM_REMOTE_PEER_ADDR+"}", rs.Neighbor).Methods("GET")
-	// r.HandleFunc(neighbors, rs.Neighbors).Methods("GET")
-	// r.HandleFunc(adjRibIn+"/{"+PARAM_REMOTE_PEER_ADDR+"}", rs.AdjRibIn).Methods("GET")
-	// r.HandleFunc(adjRibOut+"/{"+PARAM_REMOTE_PEER_ADDR+"}", rs.AdjRibOut).Methods("GET")
-	// r.HandleFunc(adjRibLocal+"/{"+PARAM_REMOTE_PEER_ADDR+"}", rs.AdjRibLocal).Methods("GET")
-	// r.HandleFunc(adjRibLocalBest+"/{"+PARAM_REMOTE_PEER_ADDR+"}", rs.AdjRibLocalBest).Methods("GET")
+	// set URLs. Every handler but the websocket Subscribe upgrade is
+	// wrapped in instrument() so request_duration_seconds covers the
+	// whole REST surface.
+	r.HandleFunc(neighbor+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(neighbor, rs.Neighbor)).Methods("GET")
+	// r.HandleFunc(neighbors, instrument(neighbors, rs.Neighbors)).Methods("GET")
+	r.HandleFunc(adjRibIn+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(adjRibIn, rs.AdjRibIn)).Methods("GET")
+	r.HandleFunc(adjRibOut+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(adjRibOut, rs.AdjRibOut)).Methods("GET")
+	r.HandleFunc(adjRibLocalBest+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(adjRibLocalBest, rs.AdjRibLocalBest)).Methods("GET")
+	r.HandleFunc(adjRibLocal+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(adjRibLocal, rs.AdjRibLocal)).Methods("GET")
+	r.HandleFunc(subscribe, rs.Subscribe)
+	r.HandleFunc(neighbor, instrument(neighbor, rs.AddNeighbor)).Methods("POST")
+	r.HandleFunc(neighbor+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(neighbor, rs.UpdateNeighbor)).Methods("PUT")
+	r.HandleFunc(neighbor+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(neighbor, rs.DeleteNeighbor)).Methods("DELETE")
+	r.HandleFunc(adjRibLocal+"/{"+PARAM_REMOTE_PEER_ADDR+"}", instrument(adjRibLocal, rs.InjectRoute)).Methods("POST")
+	r.HandleFunc(METRICS, instrument(METRICS, rs.Metrics)).Methods("GET")
 
 	// Handler when not found url
 	r.NotFoundHandler = http.HandlerFunc(NotFoundHandler)
@@ -177,7 +223,7 @@ func (rs *RestServer) Neighbor(w http.ResponseWriter, r *http.Request) {
 	logger.Debugf("Look up neighbor with the remote address : %v", remoteAddr)
 
 	//Send channel of request parameter.
-	req := NewRestRequest(REQ_NEIGHBOR, remoteAddr)
+	req := NewGobgpRequest(REQ_NEIGHBOR, remoteAddr)
 	rs.bgpServerCh <- req
 
 	//Wait response
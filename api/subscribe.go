@@ -0,0 +1,212 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// how often a ping frame is sent so the client can detect a dead connection.
+const subscribePingInterval = 30 * time.Second
+
+var subscribeUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// accept cross origin requests so a dashboard served from a
+	// different host/port can open the stream directly.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Topic filter sent by the client as the first frame after the
+// websocket handshake completes, e.g.
+//   {"remoteAddr": "10.0.0.1", "ribType": "adj-rib-in"}
+// An empty RemoteAddr/RibType means "no filter".
+type SubscribeFilter struct {
+	RemoteAddr string `json:"remoteAddr"`
+	RibType    string `json:"ribType"`
+}
+
+// Event pushed to a subscriber. Type distinguishes the payload so a
+// single client can multiplex neighbor state, adj-rib-in and
+// adj-rib-local best path events on one connection.
+type SubscribeEvent struct {
+	Type       string      `json:"type"`
+	RemoteAddr string      `json:"remoteAddr"`
+	Data       interface{} `json:"data"`
+}
+
+const (
+	EVENT_NEIGHBOR_STATE = "neighbor-state"
+	EVENT_ADJ_RIB_IN     = "adj-rib-in"
+	EVENT_BEST_PATH      = "best-path"
+)
+
+// Carried on GobgpRequest.Subscribe for REQ_SUBSCRIBE_* requests. The
+// peer goroutine that owns RemoteAddr (or all peers, if RemoteAddr is
+// empty) pushes SubscribeEvents into EventCh until QuitCh is closed.
+type SubscribeRequest struct {
+	RemoteAddr string
+	RibType    string
+	EventCh    chan *SubscribeEvent
+	QuitCh     chan struct{}
+}
+
+func newSubscribeGobgpRequest(reqType int, filter SubscribeFilter) *GobgpRequest {
+	return &GobgpRequest{
+		RequestType: reqType,
+		RemoteAddr:  filter.RemoteAddr,
+		Subscribe: &SubscribeRequest{
+			RemoteAddr: filter.RemoteAddr,
+			RibType:    filter.RibType,
+			EventCh:    make(chan *SubscribeEvent, 64),
+			QuitCh:     make(chan struct{}),
+		},
+	}
+}
+
+// the REQ_SUBSCRIBE_* request types to fan out for a given filter; an
+// empty RibType subscribes to every topic for RemoteAddr.
+func subscribeRequestTypes(filter SubscribeFilter) []int {
+	switch filter.RibType {
+	case EVENT_NEIGHBOR_STATE:
+		return []int{REQ_SUBSCRIBE_NEIGHBOR_STATE}
+	case EVENT_ADJ_RIB_IN:
+		return []int{REQ_SUBSCRIBE_ADJ_RIB_IN}
+	case EVENT_BEST_PATH:
+		return []int{REQ_SUBSCRIBE_ADJ_RIB_LOCAL_BEST}
+	default:
+		return []int{REQ_SUBSCRIBE_NEIGHBOR_STATE, REQ_SUBSCRIBE_ADJ_RIB_IN, REQ_SUBSCRIBE_ADJ_RIB_LOCAL_BEST}
+	}
+}
+
+// subscribeEvents issues the REQ_SUBSCRIBE_* requests for filter and
+// fans their per-topic EventChs into a single channel. The returned
+// stop func closes every QuitCh and blocks until the forwarder
+// goroutines it started have exited, so callers (REST and gRPC alike)
+// never leak a goroutine parked on a producer that outlives the
+// subscriber.
+func subscribeEvents(bgpServerCh chan *GobgpRequest, filter SubscribeFilter) (chan *SubscribeEvent, func()) {
+	done := make(chan struct{})
+	events := make(chan *SubscribeEvent, 64)
+
+	reqs := make([]*GobgpRequest, 0, 3)
+	var forwarders sync.WaitGroup
+	for _, reqType := range subscribeRequestTypes(filter) {
+		req := newSubscribeGobgpRequest(reqType, filter)
+		bgpServerCh <- req
+		reqs = append(reqs, req)
+
+		forwarders.Add(1)
+		go func(sub *SubscribeRequest) {
+			defer forwarders.Done()
+			for {
+				select {
+				case ev, ok := <-sub.EventCh:
+					if !ok {
+						return
+					}
+					select {
+					case events <- ev:
+					case <-done:
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}(req.Subscribe)
+	}
+
+	stop := func() {
+		for _, req := range reqs {
+			close(req.Subscribe.QuitCh)
+		}
+		close(done)
+		forwarders.Wait()
+	}
+	return events, stop
+}
+
+// Http request to upgrade to a websocket and stream neighbor state
+// transitions, adj-rib-in updates and adj-rib-local best path changes.
+//   -- curl -i -N -H "Connection: Upgrade" -H "Upgrade: websocket" \
+//        http://<ownIP>:3000/v1/bgp/subscribe
+// The client must send a single SubscribeFilter JSON frame right after
+// the handshake to select which peer/RIB it wants to watch.
+func (rs *RestServer) Subscribe(w http.ResponseWriter, r *http.Request) {
+	conn, err := subscribeUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debugf("failed to upgrade to websocket: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	var filter SubscribeFilter
+	if err := conn.ReadJSON(&filter); err != nil {
+		logger.Debugf("failed to read subscribe filter: %s", err)
+		return
+	}
+
+	// A subscriber that goes dark without a clean TCP close (NAT/idle
+	// timeout, black-holed route, sleeping laptop) would otherwise
+	// never be noticed: WriteJSON/WriteControl keep succeeding into
+	// the OS socket buffer long after the peer is gone. Require a pong
+	// within 2x the ping interval, and run a read loop to pump pongs
+	// (and any other control frames) into the pong handler -- gorilla
+	// only processes them during a Read call.
+	readDeadline := 2 * subscribePingInterval
+	conn.SetReadDeadline(time.Now().Add(readDeadline))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(readDeadline))
+		return nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	events, stop := subscribeEvents(rs.bgpServerCh, filter)
+	defer stop()
+
+	ticker := time.NewTicker(subscribePingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-events:
+			if err := conn.WriteJSON(ev); err != nil {
+				logger.Debugf("failed to write subscribe event: %s", err)
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				logger.Debugf("failed to write ping: %s", err)
+				return
+			}
+		case <-done:
+			logger.Debugf("subscriber %s did not respond within %s, closing", r.RemoteAddr, readDeadline)
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2014 Nippon Telegraph and Telephone Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeRequestTypesFiltered(t *testing.T) {
+	cases := []struct {
+		ribType string
+		want    []int
+	}{
+		{EVENT_NEIGHBOR_STATE, []int{REQ_SUBSCRIBE_NEIGHBOR_STATE}},
+		{EVENT_ADJ_RIB_IN, []int{REQ_SUBSCRIBE_ADJ_RIB_IN}},
+		{EVENT_BEST_PATH, []int{REQ_SUBSCRIBE_ADJ_RIB_LOCAL_BEST}},
+	}
+	for _, c := range cases {
+		got := subscribeRequestTypes(SubscribeFilter{RibType: c.ribType})
+		if len(got) != 1 || got[0] != c.want[0] {
+			t.Fatalf("subscribeRequestTypes(%q) = %v, want %v", c.ribType, got, c.want)
+		}
+	}
+}
+
+func TestSubscribeRequestTypesUnfilteredSubscribesToEverything(t *testing.T) {
+	got := subscribeRequestTypes(SubscribeFilter{})
+	want := []int{REQ_SUBSCRIBE_NEIGHBOR_STATE, REQ_SUBSCRIBE_ADJ_RIB_IN, REQ_SUBSCRIBE_ADJ_RIB_LOCAL_BEST}
+	if len(got) != len(want) {
+		t.Fatalf("subscribeRequestTypes({}) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("subscribeRequestTypes({}) = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubscribeEventsStopDoesNotLeakForwarders(t *testing.T) {
+	filter := SubscribeFilter{RibType: EVENT_NEIGHBOR_STATE}
+	bgpServerCh := make(chan *GobgpRequest, 1)
+
+	events, stop := subscribeEvents(bgpServerCh, filter)
+
+	req := <-bgpServerCh
+	req.Subscribe.EventCh <- &SubscribeEvent{Type: EVENT_NEIGHBOR_STATE}
+	if ev := <-events; ev.Type != EVENT_NEIGHBOR_STATE {
+		t.Fatalf("expected forwarded event, got %v", ev)
+	}
+
+	// A producer that keeps pushing after the subscriber stops must not
+	// wedge the forwarder goroutine stop() waits on.
+	done := make(chan struct{})
+	go func() {
+		stop()
+		close(done)
+	}()
+	req.Subscribe.EventCh <- &SubscribeEvent{Type: EVENT_NEIGHBOR_STATE}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stop() did not return; forwarder goroutine leaked")
+	}
+}